@@ -0,0 +1,124 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/gdesouza/godockerfile/types"
+)
+
+// ExpandVariables resolves ${VAR}/$VAR references throughout cfg in place,
+// using cfg.Resolve (BuildArgs+Env) for most fields and cfg.ResolveFromArgs
+// for BaseImage, which only ever sees ARGs declared before FROM. It is a
+// no-op unless cfg.ExpandVariables is set, and is the prerequisite step
+// before calling cfg.GenerateDockerfileContent when a config was built with
+// unexpanded ${...} references, e.g. by ParseDockerfile.
+func ExpandVariables(cfg *types.DockerfileConfig) error {
+	if !cfg.ExpandVariables {
+		return nil
+	}
+	if err := expandEnv(cfg); err != nil {
+		return err
+	}
+	if err := expandStageFields(cfg); err != nil {
+		return err
+	}
+	for i := range cfg.Stages {
+		if err := expandStage(cfg, &cfg.Stages[i]); err != nil {
+			return fmt.Errorf("stage %d (%s): %w", i, cfg.Stages[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// expandEnv resolves ${VAR}/$VAR references within cfg.Env's own values
+// against BuildArgs and the rest of Env, so an ENV declaration can build on
+// an ARG or another ENV (e.g. ENV GO_VERSION=${VERSION}).
+func expandEnv(cfg *types.DockerfileConfig) error {
+	for name, value := range cfg.Env {
+		resolved, err := cfg.Resolve(value)
+		if err != nil {
+			return err
+		}
+		cfg.Env[name] = resolved
+	}
+	return nil
+}
+
+func expandStageFields(cfg *types.DockerfileConfig) error {
+	var err error
+	if cfg.BaseImage, err = cfg.ResolveFromArgs(cfg.BaseImage); err != nil {
+		return err
+	}
+	if cfg.Workspace, err = cfg.Resolve(cfg.Workspace); err != nil {
+		return err
+	}
+	if cfg.User, err = cfg.Resolve(cfg.User); err != nil {
+		return err
+	}
+	if cfg.RunCommand, err = cfg.Resolve(cfg.RunCommand); err != nil {
+		return err
+	}
+	if cfg.BuildCommand, err = cfg.Resolve(cfg.BuildCommand); err != nil {
+		return err
+	}
+	if cfg.Entrypoint, err = cfg.Resolve(cfg.Entrypoint); err != nil {
+		return err
+	}
+	for i := range cfg.CopyFiles {
+		if cfg.CopyFiles[i].Origin, err = cfg.Resolve(cfg.CopyFiles[i].Origin); err != nil {
+			return err
+		}
+		if cfg.CopyFiles[i].Destination, err = cfg.Resolve(cfg.CopyFiles[i].Destination); err != nil {
+			return err
+		}
+	}
+	for i := range cfg.OrderedDockerCmds {
+		if err := expandDockerCmd(cfg, &cfg.OrderedDockerCmds[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expandStage(cfg *types.DockerfileConfig, stage *types.Stage) error {
+	var err error
+	if stage.BaseImage, err = cfg.ResolveFromArgs(stage.BaseImage); err != nil {
+		return err
+	}
+	if stage.Workspace, err = cfg.Resolve(stage.Workspace); err != nil {
+		return err
+	}
+	if stage.User, err = cfg.Resolve(stage.User); err != nil {
+		return err
+	}
+	if stage.BuildCommand, err = cfg.Resolve(stage.BuildCommand); err != nil {
+		return err
+	}
+	for i := range stage.CopyFiles {
+		if stage.CopyFiles[i].Origin, err = cfg.Resolve(stage.CopyFiles[i].Origin); err != nil {
+			return err
+		}
+		if stage.CopyFiles[i].Destination, err = cfg.Resolve(stage.CopyFiles[i].Destination); err != nil {
+			return err
+		}
+	}
+	for i := range stage.OrderedDockerCmds {
+		if err := expandDockerCmd(cfg, &stage.OrderedDockerCmds[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expandDockerCmd(cfg *types.DockerfileConfig, cmd *types.DockerCmd) error {
+	var err error
+	if cmd.Command, err = cfg.Resolve(cmd.Command); err != nil {
+		return err
+	}
+	for i := range cmd.Args {
+		if cmd.Args[i], err = cfg.Resolve(cmd.Args[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
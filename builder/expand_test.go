@@ -0,0 +1,63 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/gdesouza/godockerfile/types"
+)
+
+// TestExpandVariables checks that BaseImage, Workspace and a RUN command are
+// all expanded against the declared ARG/ENV once ExpandVariables is set.
+func TestExpandVariables(t *testing.T) {
+	cfg := &types.DockerfileConfig{
+		ExpandVariables: true,
+		BaseImage:       "golang:${GO_VERSION}",
+		Workspace:       "/src/${APP_NAME}",
+	}
+	cfg.AddArg("GO_VERSION", "1.22")
+	cfg.AddEnv("APP_NAME", "widget")
+	cfg.AddOrderedDockerCmd(types.DockerCmd{Type: types.RUN, Command: "echo building ${APP_NAME}"})
+
+	if err := ExpandVariables(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if cfg.BaseImage != "golang:1.22" {
+		t.Errorf("expected BaseImage 'golang:1.22', got %q", cfg.BaseImage)
+	}
+	if cfg.Workspace != "/src/widget" {
+		t.Errorf("expected Workspace '/src/widget', got %q", cfg.Workspace)
+	}
+	if cfg.OrderedDockerCmds[0].Command != "echo building widget" {
+		t.Errorf("expected expanded RUN command, got %q", cfg.OrderedDockerCmds[0].Command)
+	}
+}
+
+// TestExpandVariables_EnvReferencesArg checks that an ENV value referencing
+// an ARG is itself expanded, not just fields like BaseImage/Workspace.
+func TestExpandVariables_EnvReferencesArg(t *testing.T) {
+	cfg := &types.DockerfileConfig{ExpandVariables: true}
+	cfg.AddArg("VERSION", "1.2.3")
+	cfg.AddEnv("GO_VERSION", "${VERSION}")
+
+	if err := ExpandVariables(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if cfg.Env["GO_VERSION"] != "1.2.3" {
+		t.Errorf("expected Env[GO_VERSION] '1.2.3', got %q", cfg.Env["GO_VERSION"])
+	}
+}
+
+// TestExpandVariables_Disabled checks that ExpandVariables is a no-op unless
+// cfg.ExpandVariables is set.
+func TestExpandVariables_Disabled(t *testing.T) {
+	cfg := &types.DockerfileConfig{BaseImage: "golang:${GO_VERSION}"}
+
+	if err := ExpandVariables(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.BaseImage != "golang:${GO_VERSION}" {
+		t.Errorf("expected BaseImage to be left untouched, got %q", cfg.BaseImage)
+	}
+}
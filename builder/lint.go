@@ -0,0 +1,198 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gdesouza/godockerfile/types"
+)
+
+// Severity is how serious a LintFinding is.
+type Severity string
+
+const (
+	SeverityError Severity = "error"
+	SeverityWarn  Severity = "warn"
+	SeverityInfo  Severity = "info"
+)
+
+// LintFinding is a single issue surfaced by Lint.
+type LintFinding struct {
+	Severity Severity
+	Rule     string
+	Message  string
+}
+
+// Lint checks cfg against a set of Dockerfile best-practice rules (mirroring
+// hadolint's DL30xx/DL4000 checks, plus a few specific to this generator)
+// and returns every finding. An empty slice means no issues were found.
+func Lint(cfg *types.DockerfileConfig) []LintFinding {
+	var findings []LintFinding
+
+	for _, sv := range stageViews(cfg) {
+		findings = append(findings, lintDependencies(sv)...)
+		findings = append(findings, lintUser(sv)...)
+		findings = append(findings, lintOrderedCmds(sv)...)
+	}
+
+	if cfg.AppPort > 0 && !cfg.ExposePort {
+		findings = append(findings, LintFinding{
+			Severity: SeverityWarn,
+			Rule:     "EXPOSE-PORT",
+			Message:  fmt.Sprintf("AppPort %d is set but ExposePort is false; the port will not be EXPOSEd", cfg.AppPort),
+		})
+	}
+	if cfg.Workspace != "" && !strings.HasPrefix(cfg.Workspace, "/") {
+		findings = append(findings, LintFinding{
+			Severity: SeverityError,
+			Rule:     "WORKDIR-ABSOLUTE",
+			Message:  fmt.Sprintf("Workspace %q should be an absolute path", cfg.Workspace),
+		})
+	}
+
+	return findings
+}
+
+// stageView is the subset of fields shared by the implicit single stage and
+// an explicit types.Stage, so lint rules only need to be written once.
+type stageView struct {
+	label             string
+	dependencies      []string
+	user              string
+	orderedDockerCmds []types.DockerCmd
+}
+
+func stageViews(cfg *types.DockerfileConfig) []stageView {
+	if len(cfg.Stages) == 0 {
+		return []stageView{{
+			dependencies:      cfg.Dependencies,
+			user:              cfg.User,
+			orderedDockerCmds: cfg.OrderedDockerCmds,
+		}}
+	}
+	views := make([]stageView, len(cfg.Stages))
+	for i, s := range cfg.Stages {
+		views[i] = stageView{
+			label:             s.Name,
+			dependencies:      s.Dependencies,
+			user:              s.User,
+			orderedDockerCmds: s.OrderedDockerCmds,
+		}
+	}
+	return views
+}
+
+// lintDependencies implements DL3008: pin apt package versions.
+func lintDependencies(sv stageView) []LintFinding {
+	var findings []LintFinding
+	for _, dep := range sv.dependencies {
+		if !strings.Contains(dep, "=") {
+			findings = append(findings, LintFinding{
+				Severity: SeverityWarn,
+				Rule:     "DL3008",
+				Message:  fmt.Sprintf("pin a version for apt package %q, e.g. %s=1.2.3", dep, dep),
+			})
+		}
+	}
+	return findings
+}
+
+// lintUser implements DL3002: avoid USER root.
+func lintUser(sv stageView) []LintFinding {
+	if sv.user == "root" {
+		return []LintFinding{{Severity: SeverityWarn, Rule: "DL3002", Message: "avoid USER root; run as a non-root user"}}
+	}
+	return nil
+}
+
+// lintOrderedCmds implements DL3009, DL3020, DL3025, DL4000, and the
+// WORKDIR-before-COPY absolute-destination check, all of which only apply
+// to the OrderedDockerCmds a caller supplied directly (the generator's own
+// Dependencies/CopyFiles output already follows these rules).
+func lintOrderedCmds(sv stageView) []LintFinding {
+	var findings []LintFinding
+	sawWorkdir := false
+	for _, cmd := range sv.orderedDockerCmds {
+		switch cmd.Type {
+		case types.RUN:
+			findings = append(findings, lintRunApt(cmd)...)
+		case types.ADD:
+			if !looksLikeURL(cmd.Command) {
+				findings = append(findings, LintFinding{
+					Severity: SeverityWarn,
+					Rule:     "DL3020",
+					Message:  fmt.Sprintf("use COPY instead of ADD for local file %q", cmd.Command),
+				})
+			}
+		case types.ENTRYPOINT, types.CMD:
+			if strings.Contains(cmd.Command, " ") && len(cmd.Args) == 0 {
+				findings = append(findings, LintFinding{
+					Severity: SeverityWarn,
+					Rule:     "DL3025",
+					Message:  fmt.Sprintf("use exec form (a JSON array) for %s", cmd.Type),
+				})
+			}
+		case types.MAINTAINER:
+			findings = append(findings, LintFinding{Severity: SeverityWarn, Rule: "DL4000", Message: "MAINTAINER is deprecated; use a LABEL instead"})
+		case types.WORKDIR:
+			sawWorkdir = true
+		case types.COPY:
+			if !sawWorkdir {
+				continue
+			}
+			dest := cmd.Command
+			if len(cmd.Args) > 0 {
+				dest = cmd.Args[len(cmd.Args)-1]
+			}
+			if !strings.HasPrefix(dest, "/") {
+				findings = append(findings, LintFinding{
+					Severity: SeverityError,
+					Rule:     "COPY-DEST-ABSOLUTE",
+					Message:  fmt.Sprintf("COPY destination %q should be absolute once WORKDIR is set", dest),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// lintRunApt implements DL3009: combine `apt-get update`, `apt-get install`
+// and list cleanup into the same RUN layer.
+func lintRunApt(cmd types.DockerCmd) []LintFinding {
+	if !strings.Contains(cmd.Command, "apt-get update") {
+		return nil
+	}
+	if !strings.Contains(cmd.Command, "apt-get install") {
+		return []LintFinding{{Severity: SeverityWarn, Rule: "DL3009", Message: "combine 'apt-get update' with 'apt-get install' in the same RUN layer"}}
+	}
+	if !strings.Contains(cmd.Command, "rm -rf /var/lib/apt/lists") {
+		return []LintFinding{{Severity: SeverityWarn, Rule: "DL3009", Message: "clean up apt lists (rm -rf /var/lib/apt/lists/*) in the same RUN layer"}}
+	}
+	return nil
+}
+
+func looksLikeURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// FormatFindings renders findings as either "text" (one line per finding) or
+// "json".
+func FormatFindings(findings []LintFinding, format string) (string, error) {
+	switch format {
+	case "", "text":
+		var b strings.Builder
+		for _, f := range findings {
+			fmt.Fprintf(&b, "[%s] %s: %s\n", f.Severity, f.Rule, f.Message)
+		}
+		return b.String(), nil
+	case "json":
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("marshaling lint findings: %w", err)
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("unknown lint format %q, expected text or json", format)
+	}
+}
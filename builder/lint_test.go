@@ -0,0 +1,90 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdesouza/godockerfile/types"
+)
+
+// TestLint_Clean checks that a config that already follows every rule
+// produces no findings.
+func TestLint_Clean(t *testing.T) {
+	cfg := &types.DockerfileConfig{
+		BaseImage:    "golang:1.22",
+		Dependencies: []string{"curl=7.88.1-10"},
+		AppPort:      8080,
+		ExposePort:   true,
+		Workspace:    "/src/app",
+		User:         "app",
+	}
+
+	if findings := Lint(cfg); len(findings) != 0 {
+		t.Fatalf("expected no findings, got %+v", findings)
+	}
+}
+
+// TestLint_Findings checks that each baseline rule fires for the config
+// that violates it.
+func TestLint_Findings(t *testing.T) {
+	cfg := &types.DockerfileConfig{
+		BaseImage:    "golang:1.22",
+		Dependencies: []string{"curl"},
+		AppPort:      8080,
+		ExposePort:   false,
+		Workspace:    "relative/path",
+		User:         "root",
+		OrderedDockerCmds: []types.DockerCmd{
+			{Type: types.RUN, Command: "apt-get update"},
+			{Type: types.ADD, Command: "./app.tar.gz"},
+			{Type: types.ENTRYPOINT, Command: "./run.sh --flag"},
+			{Type: types.MAINTAINER, Command: "someone@example.com"},
+			{Type: types.WORKDIR, Command: "/src"},
+			{Type: types.COPY, Command: "main.go", Args: []string{"main.go"}},
+		},
+	}
+
+	findings := Lint(cfg)
+
+	wantRules := []string{
+		"DL3008", "DL3002", "DL3009", "DL3020", "DL3025", "DL4000",
+		"EXPOSE-PORT", "WORKDIR-ABSOLUTE", "COPY-DEST-ABSOLUTE",
+	}
+	for _, rule := range wantRules {
+		found := false
+		for _, f := range findings {
+			if f.Rule == rule {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected a finding for rule %s, got %+v", rule, findings)
+		}
+	}
+}
+
+// TestFormatFindings checks both supported output formats.
+func TestFormatFindings(t *testing.T) {
+	findings := []LintFinding{{Severity: SeverityWarn, Rule: "DL3002", Message: "avoid USER root"}}
+
+	text, err := FormatFindings(findings, "text")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.Contains(text, "DL3002") || !strings.Contains(text, "warn") {
+		t.Errorf("expected text output to mention rule and severity, got %q", text)
+	}
+
+	jsonOut, err := FormatFindings(findings, "json")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.Contains(jsonOut, "\"DL3002\"") {
+		t.Errorf("expected json output to mention rule, got %q", jsonOut)
+	}
+
+	if _, err := FormatFindings(findings, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
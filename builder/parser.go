@@ -0,0 +1,348 @@
+package builder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gdesouza/godockerfile/types"
+)
+
+// stageAccumulator mirrors types.Stage while a single FROM block is being
+// parsed; it is flattened into the final DockerfileConfig once parsing
+// finishes.
+type stageAccumulator struct {
+	name              string
+	baseImage         string
+	platform          string
+	dependencies      []string
+	preRunCommands    []string
+	buildCommand      string
+	workspace         string
+	user              string
+	orderedDockerCmds []types.DockerCmd
+}
+
+func (s *stageAccumulator) toStage() types.Stage {
+	return types.Stage{
+		Name:              s.name,
+		BaseImage:         s.baseImage,
+		Platform:          s.platform,
+		Dependencies:      s.dependencies,
+		PreRunCommands:    s.preRunCommands,
+		BuildCommand:      s.buildCommand,
+		Workspace:         s.workspace,
+		User:              s.user,
+		OrderedDockerCmds: s.orderedDockerCmds,
+		// COPY instructions live in OrderedDockerCmds (alongside RUN/ADD) so
+		// their relative order survives the round trip; CopyFiles is left
+		// empty and SkipDefaultCopy tells the generator not to mistake that
+		// for "no COPY was specified".
+		SkipDefaultCopy: true,
+	}
+}
+
+// ParseFile reads and parses the Dockerfile at path.
+func ParseFile(path string) (*types.DockerfileConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+	return ParseDockerfile(f)
+}
+
+// ParseDockerfile tokenizes an existing Dockerfile into a
+// types.DockerfileConfig, so that it can be mutated and re-emitted with
+// DockerfileConfig.GenerateDockerfileContent. It resolves line
+// continuations, strips comments (honoring leading `# syntax=` and
+// `# escape=` directives), and decodes both shell-form and JSON-exec-form
+// RUN/CMD/ENTRYPOINT instructions. An ARG declared before the first FROM is
+// collected into DockerfileConfig.BuildArgs rather than rejected, matching
+// the one instruction the spec allows ahead of FROM (e.g. to parameterize
+// the base image via FROM golang:${VERSION}).
+func ParseDockerfile(r io.Reader) (*types.DockerfileConfig, error) {
+	scanner := bufio.NewScanner(r)
+	var rawLines []string
+	for scanner.Scan() {
+		rawLines = append(rawLines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading Dockerfile: %w", err)
+	}
+
+	escape := parseEscapeDirective(rawLines)
+	lines := joinContinuations(rawLines, escape)
+
+	var stages []*stageAccumulator
+	var current *stageAccumulator
+	preFromArgs := make(map[string]string)
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		verb, rest := splitInstruction(trimmed)
+		switch verb {
+		case "FROM":
+			image, name, platform := parseFrom(rest)
+			current = &stageAccumulator{baseImage: image, name: name, platform: platform}
+			stages = append(stages, current)
+		case "ARG":
+			if current == nil {
+				// ARG is explicitly allowed before any FROM, e.g. to
+				// parameterize the base image itself (FROM golang:${VERSION}).
+				name, value := parseArg(rest)
+				preFromArgs[name] = value
+				continue
+			}
+			if err := applyInstruction(current, verb, rest); err != nil {
+				return nil, err
+			}
+		case "":
+			return nil, fmt.Errorf("malformed instruction: %q", trimmed)
+		default:
+			if current == nil {
+				return nil, fmt.Errorf("instruction %s before any FROM", verb)
+			}
+			if err := applyInstruction(current, verb, rest); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("no FROM instruction found")
+	}
+
+	config := &types.DockerfileConfig{}
+	for name, value := range preFromArgs {
+		config.AddArg(name, value)
+	}
+	if len(stages) == 1 && stages[0].name == "" {
+		// Single, unnamed stage: flatten into the top-level fields so the
+		// round trip stays backward compatible with single-stage configs.
+		s := stages[0]
+		config.BaseImage = s.baseImage
+		config.Dependencies = s.dependencies
+		config.PreRunCommands = s.preRunCommands
+		config.BuildCommand = s.buildCommand
+		config.Workspace = s.workspace
+		config.User = s.user
+		config.OrderedDockerCmds = s.orderedDockerCmds
+		config.SkipDefaultCopy = true
+	} else {
+		for _, s := range stages {
+			config.AddStage(s.toStage())
+		}
+	}
+
+	return config, nil
+}
+
+// parseArg parses the argument to ARG: `name`, `name=value` or
+// `name="value"`.
+func parseArg(rest string) (name, value string) {
+	name, value, _ = strings.Cut(rest, "=")
+	name = strings.TrimSpace(name)
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	return name, value
+}
+
+func parseEscapeDirective(lines []string) byte {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "#") {
+			break // directives must precede any other content
+		}
+		body := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
+		lower := strings.ToLower(body)
+		if strings.HasPrefix(lower, "escape=") {
+			value := strings.TrimSpace(body[len("escape="):])
+			if value == "`" {
+				return '`'
+			}
+			return '\\'
+		}
+	}
+	return '\\'
+}
+
+// joinContinuations resolves line-continuation escapes, producing one
+// logical line per Dockerfile instruction.
+func joinContinuations(lines []string, escape byte) []string {
+	var out []string
+	var cur strings.Builder
+	building := false
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, " \t\r")
+		if strings.HasSuffix(trimmed, string(escape)) {
+			cur.WriteString(strings.TrimSuffix(trimmed, string(escape)))
+			cur.WriteString(" ")
+			building = true
+			continue
+		}
+		if building {
+			cur.WriteString(line)
+			out = append(out, cur.String())
+			cur.Reset()
+			building = false
+		} else {
+			out = append(out, line)
+		}
+	}
+	if building {
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+// splitInstruction splits a logical line into its upper-cased verb and the
+// (still raw) remainder.
+func splitInstruction(line string) (verb string, rest string) {
+	fields := strings.SplitN(line, " ", 2)
+	verb = strings.ToUpper(strings.TrimSpace(fields[0]))
+	if len(fields) == 2 {
+		rest = strings.TrimSpace(fields[1])
+	}
+	return verb, rest
+}
+
+// parseFrom parses the argument to FROM: `[--platform=p] image [AS name]`.
+func parseFrom(rest string) (image, name, platform string) {
+	fields := strings.Fields(rest)
+	var remaining []string
+	for _, f := range fields {
+		if strings.HasPrefix(f, "--platform=") {
+			platform = strings.TrimPrefix(f, "--platform=")
+			continue
+		}
+		remaining = append(remaining, f)
+	}
+	for i := 0; i < len(remaining); i++ {
+		if strings.EqualFold(remaining[i], "AS") && i+1 < len(remaining) {
+			name = remaining[i+1]
+			remaining = remaining[:i]
+			break
+		}
+	}
+	if len(remaining) > 0 {
+		image = remaining[0]
+	}
+	return image, name, platform
+}
+
+func applyInstruction(s *stageAccumulator, verb, rest string) error {
+	switch types.DockerCmdType(verb) {
+	case types.RUN:
+		command := decodeShellOrExec(rest)
+		if deps, ok := aptGetInstallPackages(command); ok {
+			s.dependencies = append(s.dependencies, deps...)
+			return nil
+		}
+		// Route through orderedDockerCmds, not preRunCommands, so a RUN's
+		// position relative to COPY/ADD/EXPOSE in the source Dockerfile
+		// (which are routed through the same list) survives the round trip.
+		s.orderedDockerCmds = append(s.orderedDockerCmds, types.DockerCmd{Type: types.RUN, Command: command})
+	case types.WORKDIR:
+		s.workspace = rest
+	case types.USER:
+		s.user = rest
+	case types.COPY:
+		// Route through orderedDockerCmds, like ADD, so a COPY's position
+		// relative to RUN/ADD/EXPOSE in the source Dockerfile survives the
+		// round trip; writeStageBody would otherwise always emit CopyFiles
+		// before orderedDockerCmds regardless of source order.
+		parts := strings.Fields(rest)
+		paths := 0
+		for _, p := range parts {
+			if !strings.HasPrefix(p, "--") {
+				paths++
+			}
+		}
+		if paths < 2 {
+			return fmt.Errorf("malformed COPY instruction: %q", rest)
+		}
+		s.orderedDockerCmds = append(s.orderedDockerCmds, types.DockerCmd{Type: types.COPY, Command: parts[0], Args: parts[1:]})
+	case types.EXPOSE:
+		s.orderedDockerCmds = append(s.orderedDockerCmds, types.DockerCmd{Type: types.EXPOSE, Command: rest})
+	case types.ENTRYPOINT:
+		command, args := decodeExecForm(rest)
+		s.orderedDockerCmds = append(s.orderedDockerCmds, types.DockerCmd{Type: types.ENTRYPOINT, Command: command, Args: args})
+	case types.CMD:
+		command, args := decodeExecForm(rest)
+		s.orderedDockerCmds = append(s.orderedDockerCmds, types.DockerCmd{Type: types.CMD, Command: command, Args: args})
+	case types.ADD:
+		parts := strings.Fields(rest)
+		if len(parts) < 2 {
+			return fmt.Errorf("malformed ADD instruction: %q", rest)
+		}
+		s.orderedDockerCmds = append(s.orderedDockerCmds, types.DockerCmd{Type: types.ADD, Command: parts[0], Args: parts[1:]})
+	default:
+		s.orderedDockerCmds = append(s.orderedDockerCmds, types.DockerCmd{Type: types.DockerCmdType(verb), Command: rest})
+	}
+	return nil
+}
+
+// decodeShellOrExec normalizes a RUN/CMD/ENTRYPOINT argument that may be in
+// either shell form (`cmd arg1 arg2`) or JSON exec form
+// (`["cmd", "arg1", "arg2"]`) into a single shell-style string.
+func decodeShellOrExec(rest string) string {
+	if command, args := decodeExecForm(rest); len(args) > 0 || strings.HasPrefix(strings.TrimSpace(rest), "[") {
+		return strings.TrimSpace(strings.Join(append([]string{command}, args...), " "))
+	}
+	return rest
+}
+
+// decodeExecForm decodes a JSON-exec-form argument (`["a", "b"]`) into a
+// command and its arguments, using encoding/json so tokens containing a
+// literal comma (e.g. `["sh", "-c", "echo a,b"]`) survive intact. If rest is
+// not JSON-exec-form, it is returned verbatim as the command with no args.
+func decodeExecForm(rest string) (command string, args []string) {
+	trimmed := strings.TrimSpace(rest)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return trimmed, nil
+	}
+	var tokens []string
+	if err := json.Unmarshal([]byte(trimmed), &tokens); err != nil {
+		return trimmed, nil
+	}
+	if len(tokens) == 0 {
+		return "", nil
+	}
+	return tokens[0], tokens[1:]
+}
+
+// aptGetInstallPackages recognizes a `apt-get install` RUN command and
+// extracts the package list, skipping flags like -y/--no-install-recommends.
+func aptGetInstallPackages(command string) ([]string, bool) {
+	if !strings.Contains(command, "apt-get install") {
+		return nil, false
+	}
+	idx := strings.Index(command, "apt-get install")
+	after := command[idx+len("apt-get install"):]
+	// Stop at the first shell operator following the install list.
+	if cut := strings.IndexAny(after, "&|;"); cut >= 0 {
+		after = after[:cut]
+	}
+	var packages []string
+	for _, tok := range strings.Fields(after) {
+		if strings.HasPrefix(tok, "-") {
+			continue
+		}
+		packages = append(packages, tok)
+	}
+	if len(packages) == 0 {
+		return nil, false
+	}
+	return packages, true
+}
+
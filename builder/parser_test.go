@@ -0,0 +1,284 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdesouza/godockerfile/types"
+)
+
+// TestParseDockerfile_SingleStage checks that a simple, single-stage
+// Dockerfile is decoded into the expected high-level DockerfileConfig
+// fields.
+func TestParseDockerfile_SingleStage(t *testing.T) {
+	input := `FROM alpine:latest
+RUN apt-get update && apt-get install -y git curl && apt-get clean
+WORKDIR /app
+USER appuser
+COPY go.mod go.sum ./
+EXPOSE 8080
+ENTRYPOINT ["/app/app"]
+`
+	config, err := ParseDockerfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if config.BaseImage != "alpine:latest" {
+		t.Errorf("expected BaseImage 'alpine:latest', got %q", config.BaseImage)
+	}
+	if config.Workspace != "/app" {
+		t.Errorf("expected Workspace '/app', got %q", config.Workspace)
+	}
+	if config.User != "appuser" {
+		t.Errorf("expected User 'appuser', got %q", config.User)
+	}
+	if len(config.Dependencies) != 2 || config.Dependencies[0] != "git" || config.Dependencies[1] != "curl" {
+		t.Errorf("expected dependencies [git curl], got %v", config.Dependencies)
+	}
+	// COPY and EXPOSE are routed through OrderedDockerCmds, not
+	// CopyFiles/AppPort, so their position relative to each other and to RUN
+	// survives a regenerate; see TestParseDockerfile_RoundTrip.
+	if len(config.CopyFiles) != 0 {
+		t.Errorf("expected CopyFiles to stay empty for a parsed config, got %v", config.CopyFiles)
+	}
+	if !config.SkipDefaultCopy {
+		t.Error("expected SkipDefaultCopy to be set so a real COPY isn't masked by a synthetic default")
+	}
+	var sawCopy, sawExpose bool
+	for _, cmd := range config.OrderedDockerCmds {
+		switch cmd.Type {
+		case types.COPY:
+			sawCopy = true
+			if cmd.Command != "go.mod" || strings.Join(cmd.Args, " ") != "go.sum ./" {
+				t.Errorf("expected COPY go.mod go.sum ./, got %s %v", cmd.Command, cmd.Args)
+			}
+		case types.EXPOSE:
+			sawExpose = true
+			if cmd.Command != "8080" {
+				t.Errorf("expected EXPOSE 8080, got %q", cmd.Command)
+			}
+		}
+	}
+	if !sawCopy {
+		t.Error("expected a COPY in OrderedDockerCmds")
+	}
+	if !sawExpose {
+		t.Error("expected an EXPOSE in OrderedDockerCmds")
+	}
+}
+
+// TestParseDockerfile_MultiStage checks that a multi-stage Dockerfile with a
+// COPY --from produces a Stages slice rather than flattening to top-level
+// fields.
+func TestParseDockerfile_MultiStage(t *testing.T) {
+	input := `FROM golang:1.22 AS builder
+RUN go build -o app .
+
+FROM alpine:latest
+COPY --from=builder --chown=appuser /app/app /app/app
+`
+	config, err := ParseDockerfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(config.Stages) != 2 {
+		t.Fatalf("expected 2 stages, got %d", len(config.Stages))
+	}
+	builderStage, ok := config.StageByName("builder")
+	if !ok || builderStage.BaseImage != "golang:1.22" {
+		t.Errorf("expected a builder stage on golang:1.22, got %+v", builderStage)
+	}
+
+	final := config.Stages[1]
+	if len(final.OrderedDockerCmds) != 1 || final.OrderedDockerCmds[0].Type != types.COPY {
+		t.Fatalf("expected a single COPY in the final stage, got %+v", final.OrderedDockerCmds)
+	}
+	copyLine := final.OrderedDockerCmds[0].Command + " " + strings.Join(final.OrderedDockerCmds[0].Args, " ")
+	if copyLine != "--from=builder --chown=appuser /app/app /app/app" {
+		t.Errorf("expected COPY --from=builder --chown=appuser /app/app /app/app, got %q", copyLine)
+	}
+}
+
+// TestParseDockerfile_RoundTrip checks that parsing a Dockerfile and
+// regenerating it with GenerateDockerfileContent is semantically equivalent
+// to the source: in particular, that CMD decodes its JSON-exec-form instead
+// of falling through to a raw, invalid re-emission (the bug this guards
+// against), and that a RUN following a COPY still follows it once
+// regenerated.
+func TestParseDockerfile_RoundTrip(t *testing.T) {
+	input := `FROM golang:1.22
+COPY . /src
+RUN go build -o app .
+CMD ["./app", "serve"]
+`
+	config, err := ParseDockerfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	content, err := config.GenerateDockerfileContent()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !strings.Contains(content, `CMD ["./app", "serve"]`) {
+		t.Errorf("expected regenerated CMD to decode exec form, got:\n%s", content)
+	}
+
+	copyIdx := strings.Index(content, "COPY . /src")
+	runIdx := strings.Index(content, "RUN go build -o app .")
+	if copyIdx == -1 || runIdx == -1 {
+		t.Fatalf("expected both COPY and RUN lines in regenerated content, got:\n%s", content)
+	}
+	if copyIdx > runIdx {
+		t.Errorf("expected COPY to precede RUN as in the source, got:\n%s", content)
+	}
+}
+
+// TestParseDockerfile_RoundTrip_RunBeforeCopy checks the previously-broken
+// reverse ordering: a RUN that precedes a COPY in the source must still
+// precede it once regenerated, now that both route through the same
+// OrderedDockerCmds list.
+func TestParseDockerfile_RoundTrip_RunBeforeCopy(t *testing.T) {
+	input := `FROM golang:1.22
+RUN echo preparing
+COPY . /src
+EXPOSE 8080
+RUN go build -o app .
+`
+	config, err := ParseDockerfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	content, err := config.GenerateDockerfileContent()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	prepareIdx := strings.Index(content, "RUN echo preparing")
+	copyIdx := strings.Index(content, "COPY . /src")
+	exposeIdx := strings.Index(content, "EXPOSE 8080")
+	buildIdx := strings.Index(content, "RUN go build -o app .")
+	if prepareIdx == -1 || copyIdx == -1 || exposeIdx == -1 || buildIdx == -1 {
+		t.Fatalf("expected all four instructions in regenerated content, got:\n%s", content)
+	}
+	if !(prepareIdx < copyIdx && copyIdx < exposeIdx && exposeIdx < buildIdx) {
+		t.Errorf("expected source order RUN, COPY, EXPOSE, RUN to be preserved, got:\n%s", content)
+	}
+	if strings.Contains(content, "COPY . .") {
+		t.Errorf("expected no synthetic default COPY, got:\n%s", content)
+	}
+}
+
+// TestParseDockerfile_ExecFormCommaInArg checks that decodeExecForm uses a
+// real JSON decoder, so an argument containing a literal comma isn't split.
+func TestParseDockerfile_ExecFormCommaInArg(t *testing.T) {
+	input := `FROM alpine:latest
+CMD ["sh", "-c", "echo a,b"]
+`
+	config, err := ParseDockerfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	content, err := config.GenerateDockerfileContent()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.Contains(content, `CMD ["sh", "-c", "echo a,b"]`) {
+		t.Errorf("expected the comma-containing argument to survive intact, got:\n%s", content)
+	}
+}
+
+// TestParseDockerfile_ArgBeforeFrom checks that an ARG declared before the
+// first FROM is collected into BuildArgs instead of producing a parse error,
+// so the common `ARG VERSION=1.22` / `FROM golang:${VERSION}` pattern parses.
+func TestParseDockerfile_ArgBeforeFrom(t *testing.T) {
+	input := `ARG VERSION=1.22
+FROM golang:${VERSION}
+RUN go build -o app .
+`
+	config, err := ParseDockerfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if config.BuildArgs["VERSION"] != "1.22" {
+		t.Errorf("expected BuildArgs[VERSION]='1.22', got %v", config.BuildArgs)
+	}
+	if config.BaseImage != "golang:${VERSION}" {
+		t.Errorf("expected BaseImage 'golang:${VERSION}', got %q", config.BaseImage)
+	}
+
+	content, err := config.GenerateDockerfileContent()
+	if err != nil {
+		t.Fatalf("expected no error regenerating, got: %v", err)
+	}
+	if !strings.Contains(content, "ARG VERSION=1.22") {
+		t.Errorf("expected regenerated content to declare ARG VERSION=1.22, got:\n%s", content)
+	}
+}
+
+// TestParseDockerfile_NoCopyRoundTrip checks the maintainer-reported
+// regression directly: a Dockerfile with no COPY instruction at all must not
+// round-trip to one with an injected `COPY . .`, which would copy the whole
+// build context into the image.
+func TestParseDockerfile_NoCopyRoundTrip(t *testing.T) {
+	input := `FROM alpine
+CMD ["sh", "-c", "echo hi"]
+`
+	config, err := ParseDockerfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	content, err := config.GenerateDockerfileContent()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if strings.Contains(content, "COPY") {
+		t.Errorf("expected no COPY of any kind in regenerated content, got:\n%s", content)
+	}
+}
+
+// TestParseDockerfile_CopyFromDeclaredStage checks that a parsed COPY
+// --from=<stage> referencing a real, declared stage still round-trips
+// cleanly (i.e. the --from= extraction added for validateStages doesn't
+// reject the common case).
+func TestParseDockerfile_CopyFromDeclaredStage(t *testing.T) {
+	input := `FROM golang:1.22 AS builder
+RUN go build -o app .
+
+FROM alpine:latest
+COPY --from=builder /app/app /app/app
+`
+	config, err := ParseDockerfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := config.GenerateDockerfileContent(); err != nil {
+		t.Errorf("expected COPY --from=builder to be accepted, got: %v", err)
+	}
+}
+
+// TestParseDockerfile_MalformedCopy checks that a COPY with a flag but no
+// source/destination pair is rejected rather than silently accepted.
+func TestParseDockerfile_MalformedCopy(t *testing.T) {
+	input := `FROM alpine:latest
+COPY --chown=appuser /src
+`
+	if _, err := ParseDockerfile(strings.NewReader(input)); err == nil {
+		t.Error("expected an error for a COPY with only one path, got none")
+	}
+}
+
+// TestParseDockerfile_NoFrom ensures a Dockerfile lacking any FROM is
+// rejected.
+func TestParseDockerfile_NoFrom(t *testing.T) {
+	_, err := ParseDockerfile(strings.NewReader("RUN echo hi\n"))
+	if err == nil {
+		t.Fatal("expected an error for a Dockerfile with no FROM, got none")
+	}
+}
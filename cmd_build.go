@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gdesouza/godockerfile/engine"
+	"github.com/gdesouza/godockerfile/types"
+)
+
+// runBuildCommand builds a DockerfileConfig from flags, same as
+// runGenerateCommand, then hands it to the local Docker/BuildKit daemon via
+// package engine instead of writing the Dockerfile to disk.
+func runBuildCommand(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+
+	baseImage := fs.String("base", "", "Base image for the Dockerfile (required unless --stage is used)")
+	target := fs.String("target", "", "Target stage to build (optional)")
+	contextDir := fs.String("context", ".", "Build context directory")
+	push := fs.Bool("push", false, "Push the built image after building")
+
+	var stages stageFlag
+	fs.Var(&stages, "stage", "Named build stage as name:image, repeatable (optional)")
+	var tags stringListFlag
+	fs.Var(&tags, "tag", "Image tag, repeatable (optional)")
+	var platforms stringListFlag
+	fs.Var(&platforms, "platform", "Target platform, e.g. linux/amd64, repeatable (optional)")
+	var buildArgs stringListFlag
+	fs.Var(&buildArgs, "build-arg", "Build argument as name=value, repeatable (optional)")
+
+	fs.Parse(args)
+
+	if *baseImage == "" && len(stages) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --base or --stage is required")
+		os.Exit(1)
+	}
+
+	config := types.DockerfileConfig{
+		BaseImage: *baseImage,
+		Stages:    stages,
+	}
+
+	args2BuildArgs := make(map[string]string, len(buildArgs))
+	for _, kv := range buildArgs {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: invalid --build-arg %q, expected name=value\n", kv)
+			os.Exit(1)
+		}
+		args2BuildArgs[name] = value
+	}
+
+	opts := engine.BuildOptions{
+		Tags:       tags,
+		Platforms:  platforms,
+		Target:     *target,
+		BuildArgs:  args2BuildArgs,
+		Push:       *push,
+		ContextDir: *contextDir,
+		Progress:   os.Stdout,
+	}
+
+	if err := engine.Build(context.Background(), &config, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error building image: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdesouza/godockerfile/builder"
+	"github.com/gdesouza/godockerfile/types"
+)
+
+// runGenerateCommand is the original, default mode of the CLI: build a
+// DockerfileConfig from flags and write the resulting Dockerfile to disk.
+func runGenerateCommand(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+
+	// Command-line flags for all primitive DockerfileConfig fields
+	baseImage := fs.String("base", "", "Base image for the Dockerfile (required unless --stage is used)")
+	appPort := fs.Int("port", 0, "Port to expose (optional)")
+	deps := fs.String("deps", "", "Comma-separated list of dependencies (optional)")
+	buildCmd := fs.String("build", "", "Build command (optional)")
+	preRun := fs.String("prerun", "", "Comma-separated list of pre-run commands (optional)")
+	runCmd := fs.String("run", "", "Run command (optional)")
+	entrypoint := fs.String("entrypoint", "", "Entrypoint for the container (optional)")
+	workspace := fs.String("workspace", "", "Workspace directory (optional)")
+	exposePort := fs.Bool("expose", false, "Expose the application port (optional)")
+	user := fs.String("user", "", "User to run the application as (optional)")
+	outputDir := fs.String("out", ".", "Output directory for the Dockerfile (optional)")
+	lint := fs.Bool("lint", false, "Lint the config before generating and fail on any error-severity finding (optional)")
+	lintFormat := fs.String("lint-format", "text", "Lint output format: text or json (optional)")
+	expandVars := fs.Bool("expand-vars", false, "Expand ${VAR}/$VAR references in config fields against --arg/--env before generating (optional)")
+	strictVars := fs.Bool("strict-vars", false, "With --expand-vars, error on any variable with no value and no :-default (optional)")
+
+	var stages stageFlag
+	fs.Var(&stages, "stage", "Named build stage as name:image, repeatable (optional)")
+	var buildArgs stringListFlag
+	fs.Var(&buildArgs, "arg", "Build ARG as name=value, repeatable (optional)")
+	var envVars stringListFlag
+	fs.Var(&envVars, "env", "ENV declaration as name=value, repeatable (optional)")
+
+	fs.Parse(args)
+
+	// Validate required parameter: either a single-stage --base image or at
+	// least one --stage is required.
+	if *baseImage == "" && len(stages) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --base or --stage is required")
+		os.Exit(1)
+	}
+
+	// Parse comma-separated lists
+	var dependencies []string
+	if *deps != "" {
+		for _, dep := range strings.Split(*deps, ",") {
+			trimmed := strings.TrimSpace(dep)
+			if trimmed != "" {
+				dependencies = append(dependencies, trimmed)
+			}
+		}
+	}
+
+	var preRunCommands []string
+	if *preRun != "" {
+		for _, cmd := range strings.Split(*preRun, ",") {
+			trimmed := strings.TrimSpace(cmd)
+			if trimmed != "" {
+				preRunCommands = append(preRunCommands, trimmed)
+			}
+		}
+	}
+
+	// Instantiate DockerfileConfig
+	config := types.DockerfileConfig{
+		BaseImage:       *baseImage,
+		AppPort:         *appPort,
+		Dependencies:    dependencies,
+		BuildCommand:    *buildCmd,
+		PreRunCommands:  preRunCommands,
+		RunCommand:      *runCmd,
+		Entrypoint:      *entrypoint,
+		Workspace:       *workspace,
+		ExposePort:      *exposePort,
+		User:            *user,
+		Stages:          stages,
+		ExpandVariables: *expandVars,
+		StrictVars:      *strictVars,
+	}
+
+	for _, kv := range buildArgs {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: invalid --arg %q, expected name=value\n", kv)
+			os.Exit(1)
+		}
+		config.AddArg(name, value)
+	}
+	for _, kv := range envVars {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: invalid --env %q, expected name=value\n", kv)
+			os.Exit(1)
+		}
+		config.AddEnv(name, value)
+	}
+
+	// Expand ${VAR}/$VAR references against BuildArgs/Env before generating;
+	// a no-op unless --expand-vars was passed.
+	if err := builder.ExpandVariables(&config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error expanding variables: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Lint before generating, if requested
+	if *lint {
+		findings := builder.Lint(&config)
+		if len(findings) > 0 {
+			report, err := builder.FormatFindings(findings, *lintFormat)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error formatting lint findings: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprint(os.Stderr, report)
+		}
+		for _, f := range findings {
+			if f.Severity == builder.SeverityError {
+				fmt.Fprintln(os.Stderr, "Error: lint failed with error-severity findings")
+				os.Exit(1)
+			}
+		}
+	}
+
+	// Generate Dockerfile content
+	content, err := config.GenerateDockerfileContent()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating Dockerfile: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Ensure output directory exists
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Write Dockerfile
+	outPath := filepath.Join(*outputDir, "Dockerfile")
+	if err := os.WriteFile(outPath, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing Dockerfile: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Dockerfile generated at %s\n", outPath)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gdesouza/godockerfile/engine"
+)
+
+// runRunCommand starts a container from an already-built image via package
+// engine.
+func runRunCommand(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+
+	name := fs.String("name", "", "Container name (optional)")
+	detach := fs.Bool("detach", false, "Run the container in the background")
+
+	var env stringListFlag
+	fs.Var(&env, "env", "Environment variable as NAME=value, repeatable (optional)")
+	var ports stringListFlag
+	fs.Var(&ports, "port", "Port mapping as hostPort:containerPort[/proto], repeatable (optional)")
+	var mounts stringListFlag
+	fs.Var(&mounts, "mount", "Bind mount as hostPath:containerPath[:ro], repeatable (optional)")
+
+	fs.Parse(args)
+
+	positional := fs.Args()
+	if len(positional) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: image reference is required, e.g. godockerfile run [flags] <image> [cmd...]")
+		os.Exit(1)
+	}
+	image := positional[0]
+	cmd := positional[1:]
+
+	opts := engine.RunOptions{
+		Env:    env,
+		Ports:  ports,
+		Mounts: mounts,
+		Cmd:    cmd,
+		Detach: *detach,
+		Name:   *name,
+	}
+
+	id, err := engine.Run(context.Background(), image, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running container: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(id)
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dockerapi "github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/gdesouza/godockerfile/types"
+)
+
+// BuildOptions configures a Build invocation.
+type BuildOptions struct {
+	Tags       []string
+	Platforms  []string
+	Target     string
+	BuildArgs  map[string]string
+	Push       bool
+	ContextDir string    // defaults to "." when empty
+	Progress   io.Writer // receives BuildKit status events; defaults to io.Discard
+}
+
+// Build renders cfg into a Dockerfile inside opts.ContextDir and invokes the
+// local Docker/BuildKit daemon to build it, streaming progress to
+// opts.Progress.
+func Build(ctx context.Context, cfg *types.DockerfileConfig, opts BuildOptions) error {
+	content, err := cfg.GenerateDockerfileContent()
+	if err != nil {
+		return fmt.Errorf("generating Dockerfile: %w", err)
+	}
+
+	contextDir := opts.ContextDir
+	if contextDir == "" {
+		contextDir = "."
+	}
+	if err := os.WriteFile(filepath.Join(contextDir, "Dockerfile"), []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing Dockerfile into build context: %w", err)
+	}
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return fmt.Errorf("connecting to docker daemon: %w", err)
+	}
+	defer cli.Close()
+
+	buildContext, err := archiveContext(contextDir)
+	if err != nil {
+		return fmt.Errorf("preparing build context: %w", err)
+	}
+	defer buildContext.Close()
+
+	buildArgs := make(map[string]*string, len(opts.BuildArgs))
+	for k, v := range opts.BuildArgs {
+		v := v
+		buildArgs[k] = &v
+	}
+
+	resp, err := cli.ImageBuild(ctx, buildContext, dockerapi.ImageBuildOptions{
+		Tags:       opts.Tags,
+		Target:     opts.Target,
+		BuildArgs:  buildArgs,
+		Platform:   strings.Join(opts.Platforms, ","),
+		Dockerfile: "Dockerfile",
+	})
+	if err != nil {
+		return fmt.Errorf("building image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	progress := opts.Progress
+	if progress == nil {
+		progress = io.Discard
+	}
+	if _, err := io.Copy(progress, resp.Body); err != nil {
+		return fmt.Errorf("streaming build output: %w", err)
+	}
+
+	if opts.Push {
+		return pushTags(ctx, cli, opts.Tags, progress)
+	}
+	return nil
+}
+
+func pushTags(ctx context.Context, cli *client.Client, tags []string, progress io.Writer) error {
+	for _, tag := range tags {
+		rc, err := cli.ImagePush(ctx, tag, dockerapi.ImagePushOptions{})
+		if err != nil {
+			return fmt.Errorf("pushing %s: %w", tag, err)
+		}
+		_, err = io.Copy(progress, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("streaming push output for %s: %w", tag, err)
+		}
+	}
+	return nil
+}
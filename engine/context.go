@@ -0,0 +1,70 @@
+// Package engine drives an actual Docker/BuildKit daemon: it takes a
+// generated types.DockerfileConfig, writes it into a build context, and
+// invokes the local Docker engine via github.com/docker/docker/client to
+// build and optionally run the resulting image. This turns the module from
+// a text emitter into an end-to-end build tool, without shelling out to the
+// `docker` binary.
+package engine
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// archiveContext tars up contextDir for use as a Docker build context, as
+// required by the ImageBuild API.
+func archiveContext(contextDir string) (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := filepath.WalkDir(contextDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(contextDir, path)
+			if err != nil {
+				return err
+			}
+			if relPath == "." {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(relPath)
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		if err != nil {
+			pw.CloseWithError(fmt.Errorf("archiving build context %s: %w", contextDir, err))
+			return
+		}
+		pw.Close()
+	}()
+
+	return pr, nil
+}
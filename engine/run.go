@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	dockerapi "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+// RunOptions configures a Run invocation.
+type RunOptions struct {
+	Env        []string
+	Ports      []string // "hostPort:containerPort[/proto]", e.g. "8080:80/tcp"
+	Mounts     []string // "hostPath:containerPath[:ro]", bind-mount syntax
+	Entrypoint []string
+	Cmd        []string
+	Detach     bool
+	Name       string
+}
+
+// Run creates and starts a container from imageRef, returning its ID. When
+// opts.Detach is false, Run blocks until the container exits.
+func Run(ctx context.Context, imageRef string, opts RunOptions) (string, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return "", fmt.Errorf("connecting to docker daemon: %w", err)
+	}
+	defer cli.Close()
+
+	exposedPorts, portBindings, err := parsePorts(opts.Ports)
+	if err != nil {
+		return "", err
+	}
+
+	created, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:        imageRef,
+		Env:          opts.Env,
+		Entrypoint:   opts.Entrypoint,
+		Cmd:          opts.Cmd,
+		ExposedPorts: exposedPorts,
+	}, &container.HostConfig{
+		PortBindings: portBindings,
+		Binds:        opts.Mounts,
+	}, nil, nil, opts.Name)
+	if err != nil {
+		return "", fmt.Errorf("creating container: %w", err)
+	}
+
+	if err := cli.ContainerStart(ctx, created.ID, dockerapi.ContainerStartOptions{}); err != nil {
+		return created.ID, fmt.Errorf("starting container: %w", err)
+	}
+
+	if opts.Detach {
+		return created.ID, nil
+	}
+
+	statusCh, errCh := cli.ContainerWait(ctx, created.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return created.ID, fmt.Errorf("waiting for container: %w", err)
+		}
+	case <-statusCh:
+	}
+	return created.ID, nil
+}
+
+// parsePorts decodes "hostPort:containerPort[/proto]" entries into the
+// ExposedPorts/PortBindings shape the Docker API expects.
+func parsePorts(ports []string) (nat.PortSet, nat.PortMap, error) {
+	exposed := make(nat.PortSet, len(ports))
+	bindings := make(nat.PortMap, len(ports))
+
+	for _, p := range ports {
+		hostPort, containerSpec, ok := strings.Cut(p, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid port mapping %q, expected hostPort:containerPort[/proto]", p)
+		}
+		containerPort, proto, hasProto := strings.Cut(containerSpec, "/")
+		if !hasProto {
+			proto = "tcp"
+		}
+		if _, err := strconv.Atoi(hostPort); err != nil {
+			return nil, nil, fmt.Errorf("invalid host port in %q: %w", p, err)
+		}
+		port, err := nat.NewPort(proto, containerPort)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid container port in %q: %w", p, err)
+		}
+		exposed[port] = struct{}{}
+		bindings[port] = append(bindings[port], nat.PortBinding{HostPort: hostPort})
+	}
+
+	return exposed, bindings, nil
+}
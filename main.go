@@ -1,91 +1,66 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/gdesouza/godockerfile/types"
 )
 
-func main() {
-	// Command-line flags for all primitive DockerfileConfig fields
-	baseImage := flag.String("base", "", "Base image for the Dockerfile (required)")
-	appPort := flag.Int("port", 0, "Port to expose (optional)")
-	deps := flag.String("deps", "", "Comma-separated list of dependencies (optional)")
-	buildCmd := flag.String("build", "", "Build command (optional)")
-	preRun := flag.String("prerun", "", "Comma-separated list of pre-run commands (optional)")
-	runCmd := flag.String("run", "", "Run command (optional)")
-	entrypoint := flag.String("entrypoint", "", "Entrypoint for the container (optional)")
-	workspace := flag.String("workspace", "", "Workspace directory (optional)")
-	exposePort := flag.Bool("expose", false, "Expose the application port (optional)")
-	user := flag.String("user", "", "User to run the application as (optional)")
-	outputDir := flag.String("out", ".", "Output directory for the Dockerfile (optional)")
-
-	flag.Parse()
+// stageFlag collects repeated --stage name:image occurrences into a slice,
+// implementing flag.Value.
+type stageFlag []types.Stage
 
-	// Validate required parameter
-	if *baseImage == "" {
-		fmt.Fprintln(os.Stderr, "Error: --base is required")
-		os.Exit(1)
+func (s *stageFlag) String() string {
+	if s == nil {
+		return ""
 	}
-
-	// Parse comma-separated lists
-	var dependencies []string
-	if *deps != "" {
-		for _, dep := range strings.Split(*deps, ",") {
-			trimmed := strings.TrimSpace(dep)
-			if trimmed != "" {
-				dependencies = append(dependencies, trimmed)
-			}
-		}
+	parts := make([]string, 0, len(*s))
+	for _, stage := range *s {
+		parts = append(parts, fmt.Sprintf("%s:%s", stage.Name, stage.BaseImage))
 	}
+	return strings.Join(parts, ",")
+}
 
-	var preRunCommands []string
-	if *preRun != "" {
-		for _, cmd := range strings.Split(*preRun, ",") {
-			trimmed := strings.TrimSpace(cmd)
-			if trimmed != "" {
-				preRunCommands = append(preRunCommands, trimmed)
-			}
-		}
+func (s *stageFlag) Set(value string) error {
+	name, image, ok := strings.Cut(value, ":")
+	if !ok || name == "" || image == "" {
+		return fmt.Errorf("invalid --stage value %q, expected name:image", value)
 	}
+	*s = append(*s, types.Stage{Name: name, BaseImage: image})
+	return nil
+}
 
-	// Instantiate DockerfileConfig
-	config := types.DockerfileConfig{
-		BaseImage:      *baseImage,
-		AppPort:        *appPort,
-		Dependencies:   dependencies,
-		BuildCommand:   *buildCmd,
-		PreRunCommands: preRunCommands,
-		RunCommand:     *runCmd,
-		Entrypoint:     *entrypoint,
-		Workspace:      *workspace,
-		ExposePort:     *exposePort,
-		User:           *user,
-	}
+// stringListFlag collects repeated occurrences of a flag into a slice,
+// implementing flag.Value.
+type stringListFlag []string
 
-	// Generate Dockerfile content
-	content, err := config.GenerateDockerfileContent()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating Dockerfile: %v\n", err)
-		os.Exit(1)
+func (s *stringListFlag) String() string {
+	if s == nil {
+		return ""
 	}
+	return strings.Join(*s, ",")
+}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(*outputDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
-		os.Exit(1)
-	}
+func (s *stringListFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
 
-	// Write Dockerfile
-	outPath := filepath.Join(*outputDir, "Dockerfile")
-	if err := os.WriteFile(outPath, []byte(content), 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing Dockerfile: %v\n", err)
-		os.Exit(1)
+func main() {
+	// godockerfile [build|run] dispatches to the respective subcommand;
+	// with no subcommand (or any other first argument), it falls back to
+	// the original generate-only behavior for backward compatibility.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "build":
+			runBuildCommand(os.Args[2:])
+			return
+		case "run":
+			runRunCommand(os.Args[2:])
+			return
+		}
 	}
-
-	fmt.Printf("Dockerfile generated at %s\n", outPath)
+	runGenerateCommand(os.Args[1:])
 }
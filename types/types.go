@@ -0,0 +1,773 @@
+// Package types holds the data model used to describe a Dockerfile and the
+// logic to render that model back into Dockerfile text. The CLI in main.go
+// and the higher-level helpers in package builder both operate on the types
+// defined here.
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Version is the version string stamped into the header of every generated
+// Dockerfile.
+const Version = "0.1.0"
+
+// DockerCmdType enumerates the Docker commands that can appear in an
+// OrderedDockerCmds list.
+type DockerCmdType string
+
+const (
+	FROM        DockerCmdType = "FROM"
+	RUN         DockerCmdType = "RUN"
+	COPY        DockerCmdType = "COPY"
+	ADD         DockerCmdType = "ADD"
+	WORKDIR     DockerCmdType = "WORKDIR"
+	EXPOSE      DockerCmdType = "EXPOSE"
+	USER        DockerCmdType = "USER"
+	ENTRYPOINT  DockerCmdType = "ENTRYPOINT"
+	CMD         DockerCmdType = "CMD"
+	VOLUME      DockerCmdType = "VOLUME"
+	ARG         DockerCmdType = "ARG"
+	LABEL       DockerCmdType = "LABEL"
+	ENV         DockerCmdType = "ENV"
+	HEALTHCHECK DockerCmdType = "HEALTHCHECK"
+	MAINTAINER  DockerCmdType = "MAINTAINER"
+	STOP_SIGNAL DockerCmdType = "STOPSIGNAL"
+)
+
+type DockerCmd struct {
+	Type    DockerCmdType // The type of Docker command (e.g., RUN, COPY)
+	Command string        // The command to execute (e.g., "apt-get update && apt-get install -y curl")
+	Args    []string      // Additional arguments for the command, if any
+	Run     *RunOptions   // BuildKit RUN flags (--mount/--network/--security); only meaningful when Type==RUN
+}
+
+// MountType is the `type=` value of a BuildKit RUN --mount flag.
+type MountType string
+
+const (
+	MountTypeBind   MountType = "bind"
+	MountTypeCache  MountType = "cache"
+	MountTypeTmpfs  MountType = "tmpfs"
+	MountTypeSecret MountType = "secret"
+	MountTypeSSH    MountType = "ssh"
+)
+
+// Mount describes a single BuildKit RUN --mount flag.
+type Mount struct {
+	Type     MountType
+	Target   string
+	Source   string
+	From     string // stage or image to mount from, for bind mounts
+	ID       string // required for secret/ssh mounts
+	Mode     string
+	UID      string
+	GID      string
+	ReadOnly bool
+	Sharing  string // cache mounts only: shared|private|locked
+}
+
+// RunNetwork is the `--network=` value of a BuildKit RUN flag.
+type RunNetwork string
+
+const (
+	NetworkDefault RunNetwork = "default"
+	NetworkNone    RunNetwork = "none"
+	NetworkHost    RunNetwork = "host"
+)
+
+// RunSecurity is the `--security=` value of a BuildKit RUN flag.
+type RunSecurity string
+
+const (
+	SecurityInsecure RunSecurity = "insecure"
+	SecuritySandbox  RunSecurity = "sandbox"
+)
+
+// RunOptions holds the BuildKit-specific flags (`--mount`, `--network`,
+// `--security`) that can be attached to a RUN instruction.
+type RunOptions struct {
+	Mounts   []Mount
+	Network  RunNetwork
+	Security RunSecurity
+}
+
+// CopyInstruction represents a single COPY instruction in a Dockerfile,
+// specifying the source path (Origin) and the destination path (Destination)
+// within the Docker image. FromStage, when set, renders a multi-stage
+// `COPY --from=<stage>` instruction; Chown/Chmod render the matching
+// `--chown=`/`--chmod=` flags.
+type CopyInstruction struct {
+	Origin      string
+	Destination string
+	FromStage   string
+	Chown       string
+	Chmod       string
+}
+
+// Stage represents a single named build stage in a multi-stage Dockerfile.
+// It carries the same per-stage fields that DockerfileConfig exposes at the
+// top level, so a single-stage Dockerfile is just a Stage without a Name.
+type Stage struct {
+	Name              string
+	BaseImage         string
+	Platform          string // e.g. "linux/amd64", passed to FROM --platform
+	Dependencies      []string
+	CopyFiles         []CopyInstruction
+	PreRunCommands    []string
+	BuildCommand      string
+	BuildRunOptions   *RunOptions // BuildKit RUN flags applied to BuildCommand
+	Workspace         string
+	User              string
+	OrderedDockerCmds []DockerCmd
+	// SkipDefaultCopy suppresses the synthetic `COPY . .` that
+	// GenerateDockerfileContent otherwise emits when CopyFiles is empty. Set
+	// by builder.ParseDockerfile, whose parsed COPY instructions live in
+	// OrderedDockerCmds (to preserve source order relative to RUN), so an
+	// empty CopyFiles no longer means "no COPY was specified".
+	SkipDefaultCopy bool
+}
+
+// DockerfileConfig holds the configuration parameters for generating the Dockerfile.
+type DockerfileConfig struct {
+	BaseImage         string
+	AppPort           int
+	Dependencies      []string          // e.g., "git", "curl"
+	CopyFiles         []CopyInstruction // e.g., "main.go", "go.mod", "go.sum"
+	BuildCommand      string            // e.g., "go build -o app ."
+	BuildRunOptions   *RunOptions       // BuildKit RUN flags applied to BuildCommand, e.g. a cache mount
+	PreRunCommands    []string          // List of commands to run before the main CMD, e.g., "chmod +x ./app"
+	RunCommand        string            // e.g., "./app"
+	Entrypoint        string            // e.g., "/bin/sh -c"
+	Workspace         string            // Directory where the application will run, e.g., "/app"
+	ExposePort        bool
+	User              string      // New: User to run the application as, e.g., "nonroot" or "appuser"
+	OrderedDockerCmds []DockerCmd // List of generic Docker commands to include in the Dockerfile
+
+	// Stages holds the named stages of a multi-stage build, in the order
+	// they should be emitted. When empty, the top-level fields above
+	// (BaseImage, Dependencies, CopyFiles, ...) describe an implicit single
+	// unnamed stage, preserving backward compatibility with single-stage
+	// configs.
+	Stages []Stage
+
+	// BuildArgs holds ARG declarations (name -> default value). Entries
+	// referenced from BaseImage are emitted before the first FROM, as
+	// required for them to apply to it; all others are emitted after.
+	BuildArgs map[string]string
+	// Env holds ENV declarations (name -> value), emitted after the first
+	// FROM.
+	Env map[string]string
+	// ExpandVariables, when true, tells callers (see builder.ExpandVariables)
+	// to resolve ${VAR}/$VAR references in this config's fields against
+	// BuildArgs and Env before generation.
+	ExpandVariables bool
+	// StrictVars makes Resolve/ResolveFromArgs return an error for any
+	// variable with no value and no `:-default`, instead of expanding it to
+	// the empty string.
+	StrictVars bool
+	// SkipDefaultCopy suppresses the synthetic `COPY . .` that
+	// GenerateDockerfileContent otherwise emits when CopyFiles is empty. See
+	// Stage.SkipDefaultCopy for why a parsed config needs this.
+	SkipDefaultCopy bool
+}
+
+// AddPreRunCommand appends a new command to the PreRunCommands list.
+func (config *DockerfileConfig) AddPreRunCommand(command string) {
+	config.PreRunCommands = append(config.PreRunCommands, command)
+}
+
+// AddCopyFile appends a new file to the CopyFiles list.
+func (config *DockerfileConfig) AddCopyFile(files CopyInstruction) {
+	config.CopyFiles = append(config.CopyFiles, files)
+}
+
+// AddDependency appends a new dependency to the Dependencies list.
+func (config *DockerfileConfig) AddDependency(dependency string) {
+	config.Dependencies = append(config.Dependencies, dependency)
+}
+
+// AddOrderedDockerCmd appends a new generic Docker command to the OrderedDockerCmds list.
+func (config *DockerfileConfig) AddOrderedDockerCmd(cmd DockerCmd) {
+	config.OrderedDockerCmds = append(config.OrderedDockerCmds, cmd)
+}
+
+// AddArg declares an ARG with the given default value.
+func (config *DockerfileConfig) AddArg(name, value string) {
+	if config.BuildArgs == nil {
+		config.BuildArgs = make(map[string]string)
+	}
+	config.BuildArgs[name] = value
+}
+
+// AddEnv declares an ENV with the given value.
+func (config *DockerfileConfig) AddEnv(name, value string) {
+	if config.Env == nil {
+		config.Env = make(map[string]string)
+	}
+	config.Env[name] = value
+}
+
+// Resolve expands ${VAR}, $VAR, ${VAR:-default} and ${VAR:+alt} references in
+// value against this config's Env (which takes precedence) and BuildArgs,
+// honoring \$ as an escaped literal dollar sign. If StrictVars is set, a
+// variable with no value and no `:-default` form is an error.
+func (config *DockerfileConfig) Resolve(value string) (string, error) {
+	return expand(value, mergedVars(config.Env, config.BuildArgs), config.StrictVars)
+}
+
+// ResolveFromArgs expands variable references in value against only
+// BuildArgs, matching the Dockerfile rule that an ARG declared before FROM
+// is visible to FROM, while ENV (which is only ever set after a FROM) is
+// not.
+func (config *DockerfileConfig) ResolveFromArgs(value string) (string, error) {
+	return expand(value, mergedVars(nil, config.BuildArgs), config.StrictVars)
+}
+
+func mergedVars(primary, secondary map[string]string) map[string]string {
+	vars := make(map[string]string, len(primary)+len(secondary))
+	for k, v := range secondary {
+		vars[k] = v
+	}
+	for k, v := range primary {
+		vars[k] = v
+	}
+	return vars
+}
+
+// expand resolves ${VAR}/$VAR/${VAR:-default}/${VAR:+alt} references in s
+// against vars. A literal dollar sign is written as \$.
+func expand(s string, vars map[string]string, strict bool) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) && s[i+1] == '$' {
+			out.WriteByte('$')
+			i++
+			continue
+		}
+		if c != '$' {
+			out.WriteByte(c)
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '{' {
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference in %q", s)
+			}
+			expr := s[i+2 : i+2+end]
+			resolved, err := expandBraced(expr, vars, strict)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(resolved)
+			i += 2 + end
+			continue
+		}
+		j := i + 1
+		for j < len(s) && isVarNameByte(s[j]) {
+			j++
+		}
+		name := s[i+1 : j]
+		value, ok := vars[name]
+		if !ok && strict {
+			return "", fmt.Errorf("undefined variable %q", name)
+		}
+		out.WriteString(value)
+		i = j - 1
+	}
+	return out.String(), nil
+}
+
+// expandBraced resolves the contents of a ${...} reference: a bare name, or
+// one using the `:-default`/`:+alt` forms.
+func expandBraced(expr string, vars map[string]string, strict bool) (string, error) {
+	if name, def, ok := strings.Cut(expr, ":-"); ok {
+		if value, present := vars[name]; present && value != "" {
+			return value, nil
+		}
+		return def, nil
+	}
+	if name, alt, ok := strings.Cut(expr, ":+"); ok {
+		if value, present := vars[name]; present && value != "" {
+			return alt, nil
+		}
+		return "", nil
+	}
+	value, ok := vars[expr]
+	if !ok && strict {
+		return "", fmt.Errorf("undefined variable %q", expr)
+	}
+	return value, nil
+}
+
+func isVarNameByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// AddCacheMount attaches a BuildKit cache mount to BuildCommand, targeting
+// target (e.g. "/root/.cache/go-build") and keyed by id. This is the common
+// pattern for caching Go/Node build artifacts across builds without baking
+// them into the image.
+func (config *DockerfileConfig) AddCacheMount(target, id string) {
+	if config.BuildRunOptions == nil {
+		config.BuildRunOptions = &RunOptions{}
+	}
+	config.BuildRunOptions.Mounts = append(config.BuildRunOptions.Mounts, Mount{
+		Type:    MountTypeCache,
+		Target:  target,
+		ID:      id,
+		Sharing: "locked",
+	})
+}
+
+// AddStage appends a new named stage to the Stages list.
+func (config *DockerfileConfig) AddStage(stage Stage) {
+	config.Stages = append(config.Stages, stage)
+}
+
+// StageByName returns the stage with the given name, if one is defined.
+func (config *DockerfileConfig) StageByName(name string) (*Stage, bool) {
+	for i := range config.Stages {
+		if config.Stages[i].Name == name {
+			return &config.Stages[i], true
+		}
+	}
+	return nil, false
+}
+
+// validateStages checks that every CopyInstruction.FromStage across all
+// stages either names a stage defined earlier in config.Stages or looks like
+// a valid external image reference (i.e. is non-empty and contains no
+// whitespace).
+func (config *DockerfileConfig) validateStages() error {
+	defined := make(map[string]bool, len(config.Stages))
+	for i, stage := range config.Stages {
+		if stage.Name != "" {
+			defined[stage.Name] = true
+		}
+		for _, cf := range stage.CopyFiles {
+			if err := validateFromStage(cf.FromStage, defined); err != nil {
+				return fmt.Errorf("stage %d (%s): %w", i, stage.Name, err)
+			}
+		}
+		for _, cmd := range stage.OrderedDockerCmds {
+			if err := validateFromStage(copyFromStageFlag(cmd), defined); err != nil {
+				return fmt.Errorf("stage %d (%s): %w", i, stage.Name, err)
+			}
+		}
+	}
+	for _, cf := range config.CopyFiles {
+		if err := validateFromStage(cf.FromStage, defined); err != nil {
+			return err
+		}
+	}
+	for _, cmd := range config.OrderedDockerCmds {
+		if err := validateFromStage(copyFromStageFlag(cmd), defined); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFromStageFlag extracts the --from= stage reference from a COPY
+// instruction that was routed through OrderedDockerCmds (rather than
+// CopyFiles), e.g. by builder.ParseDockerfile. Returns "" for any other
+// command type or a COPY with no --from= flag.
+func copyFromStageFlag(cmd DockerCmd) string {
+	if cmd.Type != COPY {
+		return ""
+	}
+	for _, field := range append([]string{cmd.Command}, cmd.Args...) {
+		if from, ok := strings.CutPrefix(field, "--from="); ok {
+			return from
+		}
+	}
+	return ""
+}
+
+func validateFromStage(fromStage string, defined map[string]bool) error {
+	if fromStage == "" {
+		return nil
+	}
+	if defined[fromStage] {
+		return nil
+	}
+	if strings.ContainsAny(fromStage, " \t\n") || fromStage == "" {
+		return fmt.Errorf("invalid COPY --from reference %q: not a declared stage or a valid image reference", fromStage)
+	}
+	// Not a declared stage: treat it as an external image reference, e.g.
+	// `COPY --from=golang:1.22 ...`. We can't resolve those against a
+	// registry here, so only structural validation applies.
+	return nil
+}
+
+// validateRunOptions checks that every RunOptions attached to a RUN
+// instruction (via OrderedDockerCmds or BuildRunOptions, top-level or
+// per-stage) is well-formed: secret/ssh mounts carry an id, and bind mounts
+// with a `from=` reference either a declared stage or a plausible external
+// image reference.
+func (config *DockerfileConfig) validateRunOptions() error {
+	defined := make(map[string]bool, len(config.Stages))
+	for _, stage := range config.Stages {
+		if stage.Name != "" {
+			defined[stage.Name] = true
+		}
+	}
+
+	check := func(opts *RunOptions) error {
+		if opts == nil {
+			return nil
+		}
+		for _, m := range opts.Mounts {
+			if (m.Type == MountTypeSecret || m.Type == MountTypeSSH) && m.ID == "" {
+				return fmt.Errorf("%s mount on target %q requires an id", m.Type, m.Target)
+			}
+			if m.Type == MountTypeBind && m.From != "" && !defined[m.From] && strings.ContainsAny(m.From, " \t\n") {
+				return fmt.Errorf("invalid bind mount from=%q: not a declared stage or a valid image reference", m.From)
+			}
+		}
+		return nil
+	}
+
+	if err := check(config.BuildRunOptions); err != nil {
+		return err
+	}
+	for _, cmd := range config.OrderedDockerCmds {
+		if err := check(cmd.Run); err != nil {
+			return err
+		}
+	}
+	for _, stage := range config.Stages {
+		if err := check(stage.BuildRunOptions); err != nil {
+			return err
+		}
+		for _, cmd := range stage.OrderedDockerCmds {
+			if err := check(cmd.Run); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns m's keys in sorted order, for deterministic output.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitArgsByFromUsage partitions args into those referenced by fromImage
+// (which must be declared before FROM to take effect) and the rest.
+func splitArgsByFromUsage(args map[string]string, fromImage string) (before, after map[string]string) {
+	before = make(map[string]string)
+	after = make(map[string]string)
+	for name, value := range args {
+		if referencesVar(fromImage, name) {
+			before[name] = value
+		} else {
+			after[name] = value
+		}
+	}
+	return before, after
+}
+
+// referencesVar reports whether s contains a $name or ${name...} reference.
+func referencesVar(s, name string) bool {
+	if strings.Contains(s, "${"+name+"}") || strings.Contains(s, "${"+name+":") {
+		return true
+	}
+	idx := strings.Index(s, "$"+name)
+	if idx < 0 {
+		return false
+	}
+	end := idx + len("$"+name)
+	return end >= len(s) || !isVarNameByte(s[end])
+}
+
+// GenerateDockerfileContent creates the content of a Dockerfile as a string
+// based on the provided DockerfileConfig.
+func (config *DockerfileConfig) GenerateDockerfileContent() (string, error) {
+	if len(config.Stages) == 0 && config.BaseImage == "" {
+		return "", fmt.Errorf("base image cannot be empty")
+	}
+	if err := config.validateStages(); err != nil {
+		return "", err
+	}
+	if err := config.validateRunOptions(); err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+
+	// add header information
+	builder.WriteString("# Auto-generated Dockerfile\n")
+	builder.WriteString("# Do not edit this file manually\n")
+
+	// Add version information
+	builder.WriteString(fmt.Sprintf("# Dockerbot version: %s\n", Version))
+
+	// ARGs referenced by the first FROM must be declared before it; every
+	// other ARG, plus all ENV, are declared right after it instead.
+	firstImage := config.BaseImage
+	if len(config.Stages) > 0 {
+		firstImage = config.Stages[0].BaseImage
+	}
+	argsBeforeFrom, argsAfterFrom := splitArgsByFromUsage(config.BuildArgs, firstImage)
+
+	for _, name := range sortedKeys(argsBeforeFrom) {
+		builder.WriteString(fmt.Sprintf("ARG %s=%s\n", name, argsBeforeFrom[name]))
+	}
+	if len(argsBeforeFrom) > 0 {
+		builder.WriteString("\n")
+	}
+
+	var headerLines []string
+	for _, name := range sortedKeys(argsAfterFrom) {
+		headerLines = append(headerLines, fmt.Sprintf("ARG %s=%s\n", name, argsAfterFrom[name]))
+	}
+	for _, name := range sortedKeys(config.Env) {
+		headerLines = append(headerLines, fmt.Sprintf("ENV %s=%s\n", name, config.Env[name]))
+	}
+
+	if len(config.Stages) == 0 {
+		// No explicit stages: treat the top-level fields as a single
+		// implicit stage, as before.
+		writeStageBody(&builder, config.BaseImage, headerLines, config.Dependencies, config.CopyFiles, config.PreRunCommands, "", config.User, config.OrderedDockerCmds, config.BuildCommand, config.BuildRunOptions, config.SkipDefaultCopy)
+	} else {
+		for i, stage := range config.Stages {
+			builder.WriteString("\n")
+			if stage.Platform != "" {
+				builder.WriteString(fmt.Sprintf("FROM --platform=%s %s", stage.Platform, stage.BaseImage))
+			} else {
+				builder.WriteString(fmt.Sprintf("FROM %s", stage.BaseImage))
+			}
+			if stage.Name != "" {
+				builder.WriteString(fmt.Sprintf(" AS %s", stage.Name))
+			}
+			builder.WriteString("\n\n")
+			var lines []string
+			if i == 0 {
+				lines = headerLines
+			}
+			writeStageBody(&builder, "", lines, stage.Dependencies, stage.CopyFiles, stage.PreRunCommands, stage.Workspace, stage.User, stage.OrderedDockerCmds, stage.BuildCommand, stage.BuildRunOptions, stage.SkipDefaultCopy)
+		}
+	}
+
+	// Expose port if requested
+	if config.ExposePort && config.AppPort > 0 {
+		builder.WriteString(fmt.Sprintf("EXPOSE %d\n", config.AppPort))
+		builder.WriteString("\n")
+	}
+
+	// Set working directory if provided
+	if config.Workspace != "" {
+		builder.WriteString(fmt.Sprintf("WORKDIR %s\n", config.Workspace))
+		builder.WriteString("\n")
+	}
+
+	// Define the entrypoint/command to run the application
+	if config.Entrypoint != "" {
+		builder.WriteString(fmt.Sprintf("ENTRYPOINT [\"%s\"]\n", config.Entrypoint))
+	}
+
+	return builder.String(), nil
+}
+
+// writeStageBody renders the FROM-independent body shared by the implicit
+// single stage and every explicit Stage: dependency install, pre-run
+// commands, USER, WORKDIR, COPY, ordered commands and the build command.
+func writeStageBody(builder *strings.Builder, baseImage string, headerLines []string, dependencies []string, copyFiles []CopyInstruction, preRunCommands []string, workspace string, user string, orderedDockerCmds []DockerCmd, buildCommand string, buildRunOptions *RunOptions, skipDefaultCopy bool) {
+	if baseImage != "" {
+		builder.WriteString(fmt.Sprintf("FROM %s\n", baseImage))
+		builder.WriteString("\n")
+	}
+
+	// ARG/ENV declarations that weren't needed before FROM
+	if len(headerLines) > 0 {
+		for _, line := range headerLines {
+			builder.WriteString(line)
+		}
+		builder.WriteString("\n")
+	}
+
+	// Install dependencies if any
+	if len(dependencies) > 0 {
+		builder.WriteString("RUN apt-get update && apt-get install -y \\\n")
+		for _, dep := range dependencies {
+			builder.WriteString(fmt.Sprintf("    %s \\ \n", dep))
+		}
+		builder.WriteString("    && apt-get clean && rm -rf /var/lib/apt/lists/*\n")
+		builder.WriteString("\n")
+	}
+
+	// Run pre-commands if any
+	if len(preRunCommands) > 0 {
+		for _, cmd := range preRunCommands {
+			builder.WriteString(fmt.Sprintf("RUN %s\n", cmd))
+		}
+		builder.WriteString("\n")
+	}
+
+	// Set user if provided
+	if user != "" {
+		builder.WriteString(fmt.Sprintf("USER %s\n", user))
+		builder.WriteString("\n")
+	}
+
+	// Set working directory if provided
+	if workspace != "" {
+		builder.WriteString(fmt.Sprintf("WORKDIR %s\n", workspace))
+		builder.WriteString("\n")
+	}
+
+	// Copy application files
+	if len(copyFiles) > 0 {
+		for _, file := range copyFiles {
+			builder.WriteString(formatCopyInstruction(file))
+		}
+		builder.WriteString("\n")
+	} else if !skipDefaultCopy {
+		// Default copy if no specific files are provided
+		builder.WriteString("COPY . .\n")
+		builder.WriteString("\n")
+	}
+
+	// Add ordered Docker commands if any
+	if len(orderedDockerCmds) > 0 {
+		for _, cmd := range orderedDockerCmds {
+			line, err := formatDockerCmd(cmd)
+			if err != nil {
+				// Preserve the line as a comment rather than dropping it
+				// silently; callers validate cmd types ahead of generation.
+				builder.WriteString(fmt.Sprintf("# invalid docker command: %v\n", err))
+				continue
+			}
+			builder.WriteString(line)
+		}
+	}
+
+	// Build command
+	if buildCommand != "" {
+		builder.WriteString(formatRunLine(buildCommand, buildRunOptions))
+		builder.WriteString("\n")
+	}
+}
+
+// formatRunLine renders a RUN instruction, prefixing any BuildKit
+// --mount/--network/--security flags carried by opts.
+func formatRunLine(command string, opts *RunOptions) string {
+	if opts == nil {
+		return fmt.Sprintf("RUN %s\n", command)
+	}
+	var flags []string
+	for _, m := range opts.Mounts {
+		flags = append(flags, formatMount(m))
+	}
+	if opts.Network != "" && opts.Network != NetworkDefault {
+		flags = append(flags, fmt.Sprintf("--network=%s", opts.Network))
+	}
+	if opts.Security != "" {
+		flags = append(flags, fmt.Sprintf("--security=%s", opts.Security))
+	}
+	if len(flags) == 0 {
+		return fmt.Sprintf("RUN %s\n", command)
+	}
+	return fmt.Sprintf("RUN %s %s\n", strings.Join(flags, " "), command)
+}
+
+// formatMount renders a single BuildKit `--mount=type=...,...` flag.
+func formatMount(m Mount) string {
+	parts := []string{fmt.Sprintf("type=%s", m.Type)}
+	if m.Target != "" {
+		parts = append(parts, fmt.Sprintf("target=%s", m.Target))
+	}
+	if m.Source != "" {
+		parts = append(parts, fmt.Sprintf("source=%s", m.Source))
+	}
+	if m.From != "" {
+		parts = append(parts, fmt.Sprintf("from=%s", m.From))
+	}
+	if m.ID != "" {
+		parts = append(parts, fmt.Sprintf("id=%s", m.ID))
+	}
+	if m.Mode != "" {
+		parts = append(parts, fmt.Sprintf("mode=%s", m.Mode))
+	}
+	if m.UID != "" {
+		parts = append(parts, fmt.Sprintf("uid=%s", m.UID))
+	}
+	if m.GID != "" {
+		parts = append(parts, fmt.Sprintf("gid=%s", m.GID))
+	}
+	if m.ReadOnly {
+		parts = append(parts, "ro")
+	}
+	if m.Sharing != "" {
+		parts = append(parts, fmt.Sprintf("sharing=%s", m.Sharing))
+	}
+	return fmt.Sprintf("--mount=%s", strings.Join(parts, ","))
+}
+
+// formatCopyInstruction renders a single COPY instruction, including the
+// optional --from, --chown and --chmod flags used by multi-stage builds.
+func formatCopyInstruction(file CopyInstruction) string {
+	var flags strings.Builder
+	if file.FromStage != "" {
+		flags.WriteString(fmt.Sprintf("--from=%s ", file.FromStage))
+	}
+	if file.Chown != "" {
+		flags.WriteString(fmt.Sprintf("--chown=%s ", file.Chown))
+	}
+	if file.Chmod != "" {
+		flags.WriteString(fmt.Sprintf("--chmod=%s ", file.Chmod))
+	}
+	return fmt.Sprintf("COPY %s%s %s\n", flags.String(), file.Origin, file.Destination)
+}
+
+func formatDockerCmd(cmd DockerCmd) (string, error) {
+	switch cmd.Type {
+	case RUN:
+		return formatRunLine(cmd.Command, cmd.Run), nil
+	case WORKDIR, USER, MAINTAINER:
+		return fmt.Sprintf("%s %s\n", cmd.Type, cmd.Command), nil
+	case COPY, ADD:
+		if len(cmd.Args) > 0 {
+			return fmt.Sprintf("%s %s %s\n", cmd.Type, cmd.Command, strings.Join(cmd.Args, " ")), nil
+		}
+		return fmt.Sprintf("%s %s\n", cmd.Type, cmd.Command), nil
+	case EXPOSE, STOP_SIGNAL:
+		if len(cmd.Args) > 0 {
+			return fmt.Sprintf("%s %s\n", cmd.Type, strings.Join(cmd.Args, " ")), nil
+		}
+		return fmt.Sprintf("%s %s\n", cmd.Type, cmd.Command), nil
+	case ENTRYPOINT, CMD:
+		if len(cmd.Args) > 0 {
+			return fmt.Sprintf("%s [\"%s\", \"%s\"]\n", cmd.Type, cmd.Command, strings.Join(cmd.Args, "\", \"")), nil
+		}
+		return fmt.Sprintf("%s [\"%s\"]\n", cmd.Type, cmd.Command), nil
+	case VOLUME:
+		if len(cmd.Args) > 0 {
+			return fmt.Sprintf("VOLUME [\"%s\"]\n", strings.Join(cmd.Args, "\", \"")), nil
+		}
+		return fmt.Sprintf("VOLUME [\"%s\"]\n", cmd.Command), nil
+	case ARG, LABEL, ENV:
+		if len(cmd.Args) > 0 {
+			return fmt.Sprintf("%s %s=%s\n", cmd.Type, cmd.Command, strings.Join(cmd.Args, " ")), nil
+		}
+		return fmt.Sprintf("%s %s\n", cmd.Type, cmd.Command), nil
+	case HEALTHCHECK:
+		if len(cmd.Args) > 0 {
+			return fmt.Sprintf("HEALTHCHECK CMD %s\n", strings.Join(cmd.Args, " ")), nil
+		}
+		return fmt.Sprintf("HEALTHCHECK CMD %s\n", cmd.Command), nil
+	default:
+		return "", fmt.Errorf("unknown Docker command type: %s", cmd.Type)
+	}
+}
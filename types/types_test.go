@@ -0,0 +1,118 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAddCacheMount checks that AddCacheMount renders a BuildKit cache mount
+// flag on the RUN line carrying BuildCommand.
+func TestAddCacheMount(t *testing.T) {
+	config := DockerfileConfig{BaseImage: "golang:1.22", BuildCommand: "go build ./..."}
+	config.AddCacheMount("/root/.cache/go-build", "go-build-cache")
+
+	content, err := config.GenerateDockerfileContent()
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	expected := "RUN --mount=type=cache,target=/root/.cache/go-build,id=go-build-cache,sharing=locked go build ./...\n"
+	if !strings.Contains(content, expected) {
+		t.Errorf("expected Dockerfile to contain %q, got:\n%s", expected, content)
+	}
+}
+
+// TestGenerateDockerfileContent_SecretMountRequiresID ensures a secret mount
+// without an id is rejected, since BuildKit requires one to reference the
+// secret at build time.
+func TestGenerateDockerfileContent_SecretMountRequiresID(t *testing.T) {
+	config := DockerfileConfig{BaseImage: "alpine:latest"}
+	config.AddOrderedDockerCmd(DockerCmd{
+		Type:    RUN,
+		Command: "cat /run/secrets/token",
+		Run:     &RunOptions{Mounts: []Mount{{Type: MountTypeSecret}}},
+	})
+
+	_, err := config.GenerateDockerfileContent()
+	if err == nil {
+		t.Fatal("expected an error for a secret mount with no id, but got none")
+	}
+}
+
+// TestGenerateDockerfileContent_StageWorkspace checks that each Stage's
+// Workspace is rendered as its own WORKDIR line, not dropped.
+func TestGenerateDockerfileContent_StageWorkspace(t *testing.T) {
+	config := DockerfileConfig{
+		Stages: []Stage{
+			{Name: "build", BaseImage: "golang:1.22", Workspace: "/src", BuildCommand: "go build -o app ."},
+		},
+	}
+
+	content, err := config.GenerateDockerfileContent()
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if !strings.Contains(content, "WORKDIR /src\n") {
+		t.Errorf("expected Dockerfile to contain %q, got:\n%s", "WORKDIR /src\n", content)
+	}
+}
+
+// TestValidateStages_OrderedCopyFromUnknownStage checks that a COPY routed
+// through OrderedDockerCmds (e.g. by builder.ParseDockerfile) is still
+// validated against declared stage names, not just COPY routed through the
+// structured CopyFiles list.
+func TestValidateStages_OrderedCopyFromUnknownStage(t *testing.T) {
+	config := DockerfileConfig{
+		Stages: []Stage{
+			{Name: "builder", BaseImage: "golang:1.22"},
+			{
+				BaseImage: "alpine:latest",
+				OrderedDockerCmds: []DockerCmd{
+					{Type: COPY, Command: "--from=not a stage", Args: []string{"/app/app", "/app/app"}},
+				},
+			},
+		},
+	}
+
+	if _, err := config.GenerateDockerfileContent(); err == nil {
+		t.Error("expected GenerateDockerfileContent to reject an invalid COPY --from reference, got no error")
+	}
+}
+
+// TestResolve checks the supported ${VAR}/$VAR/${VAR:-default}/${VAR:+alt}
+// expansion forms, plus the \$ escape.
+func TestResolve(t *testing.T) {
+	config := DockerfileConfig{}
+	config.AddEnv("NAME", "app")
+	config.AddArg("VERSION", "")
+
+	cases := map[string]string{
+		"${NAME}":               "app",
+		"$NAME-bin":             "app-bin",
+		"${VERSION:-latest}":    "latest",
+		"${NAME:+is-set}":       "is-set",
+		"${MISSING:+is-set}":    "",
+		`price: \$NAME literal`: "price: $NAME literal",
+	}
+
+	for input, want := range cases {
+		got, err := config.Resolve(input)
+		if err != nil {
+			t.Fatalf("Resolve(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// TestResolve_StrictVars checks that an undefined variable is an error once
+// StrictVars is set.
+func TestResolve_StrictVars(t *testing.T) {
+	config := DockerfileConfig{StrictVars: true}
+
+	if _, err := config.Resolve("${UNSET}"); err == nil {
+		t.Fatal("expected an error for an undefined variable under StrictVars, but got none")
+	}
+}